@@ -0,0 +1,103 @@
+package glance
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const bilibiliAuthInfoTTL = 10 * time.Minute
+
+// bilibiliAuthInfo is what fetching the personal feeds needs beyond the raw
+// cookie: the account's mid (for the favorites endpoints) and a csrf token
+// (the bili_jct cookie value, required by Bilibili on most POST-shaped
+// reads even when issued as a GET).
+type bilibiliAuthInfo struct {
+	Mid  int64
+	Csrf string
+}
+
+type bilibiliAuthCacheEntry struct {
+	info      bilibiliAuthInfo
+	expiresAt time.Time
+}
+
+var (
+	bilibiliAuthCacheMu sync.Mutex
+	bilibiliAuthCache   = map[string]bilibiliAuthCacheEntry{}
+)
+
+// bilibiliCookieHeader builds the Cookie header value for a widget's
+// credentials, preferring a raw cookie string when given and otherwise
+// assembling one from the individual sessdata/bili_jct fields.
+func bilibiliCookieHeader(cookie, sessData, biliJct string) string {
+	if cookie != "" {
+		return cookie
+	}
+
+	if sessData == "" {
+		return ""
+	}
+
+	header := "SESSDATA=" + sessData
+	if biliJct != "" {
+		header += "; bili_jct=" + biliJct
+	}
+
+	return header
+}
+
+// bilibiliCsrfFromCookie extracts the bili_jct value out of a Cookie header
+// string, which Bilibili expects back as the `csrf` query param.
+func bilibiliCsrfFromCookie(cookieHeader string) string {
+	request := http.Request{Header: http.Header{"Cookie": []string{cookieHeader}}}
+
+	for _, c := range request.Cookies() {
+		if c.Name == "bili_jct" {
+			return c.Value
+		}
+	}
+
+	return ""
+}
+
+// resolveBilibiliAuth resolves and caches the mid/csrf pair for a cookie so
+// repeated widget updates don't re-hit the nav endpoint every time.
+func resolveBilibiliAuth(cookieHeader string) (bilibiliAuthInfo, error) {
+	bilibiliAuthCacheMu.Lock()
+	if entry, ok := bilibiliAuthCache[cookieHeader]; ok && time.Now().Before(entry.expiresAt) {
+		bilibiliAuthCacheMu.Unlock()
+		return entry.info, nil
+	}
+	bilibiliAuthCacheMu.Unlock()
+
+	request, err := http.NewRequest("GET", "https://api.bilibili.com/x/web-interface/nav", nil)
+	if err != nil {
+		return bilibiliAuthInfo{}, err
+	}
+
+	request.Header.Set("User-Agent", bilibiliUserAgent)
+	request.Header.Set("Referer", "https://www.bilibili.com/")
+	request.Header.Set("Cookie", cookieHeader)
+
+	nav, err := decodeJsonFromRequest[bilibiliNavResponseJson](defaultHTTPClient, request)
+	if err != nil {
+		return bilibiliAuthInfo{}, fmt.Errorf("fetching bilibili account info: %w", err)
+	}
+
+	if !nav.Data.IsLogin {
+		return bilibiliAuthInfo{}, fmt.Errorf("%w: bilibili cookie is not logged in", errNoContent)
+	}
+
+	info := bilibiliAuthInfo{
+		Mid:  nav.Data.Mid,
+		Csrf: bilibiliCsrfFromCookie(cookieHeader),
+	}
+
+	bilibiliAuthCacheMu.Lock()
+	bilibiliAuthCache[cookieHeader] = bilibiliAuthCacheEntry{info: info, expiresAt: time.Now().Add(bilibiliAuthInfoTTL)}
+	bilibiliAuthCacheMu.Unlock()
+
+	return info, nil
+}
@@ -0,0 +1,141 @@
+package glance
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bilibiliDanmakuPreviewWorkers caps how many videos get their danmaku
+// sampled concurrently - IncludeDanmakuPreview already doubles the request
+// volume per video (cid lookup + danmaku list), so this stays modest.
+const bilibiliDanmakuPreviewWorkers = 10
+
+const bilibiliDanmakuPreviewCount = 3
+
+type bilibiliPagelistResponseJson struct {
+	Data []struct {
+		Cid int64 `json:"cid"`
+	} `json:"data"`
+}
+
+type bilibiliDanmakuXml struct {
+	Comments []struct {
+		P    string `xml:"p,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"d"`
+}
+
+// attachBilibiliDanmakuPreviews fetches a small sample of danmaku for each
+// video and sets DanmakuPreview in place. Failures for individual videos are
+// logged and leave that video without a preview rather than failing the
+// whole widget update.
+func attachBilibiliDanmakuPreviews(videos bilibiliVideoList) {
+	sem := make(chan struct{}, bilibiliDanmakuPreviewWorkers)
+	var wg sync.WaitGroup
+
+	for i := range videos {
+		if videos[i].Bvid == "" {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(video *videoBilibili) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			preview, err := fetchBilibiliDanmakuPreview(video.Bvid)
+			if err != nil {
+				slog.Error("Failed to fetch bilibili danmaku preview", "bvid", video.Bvid, "error", err)
+				return
+			}
+
+			video.DanmakuPreview = preview
+		}(&videos[i])
+	}
+
+	wg.Wait()
+}
+
+// fetchBilibiliDanmakuPreview resolves bvid to its first part's cid, fetches
+// that part's danmaku, and returns the text of the comments that appear
+// latest in the video.
+func fetchBilibiliDanmakuPreview(bvid string) ([]string, error) {
+	cid, err := fetchBilibiliCid(bvid)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest("GET", fmt.Sprintf("https://api.bilibili.com/x/v1/dm/list.so?oid=%d", cid), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("User-Agent", bilibiliUserAgent)
+	request.Header.Set("Referer", "https://www.bilibili.com/")
+
+	response, err := defaultHTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var doc bilibiliDanmakuXml
+	if err := xml.NewDecoder(response.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing danmaku xml: %w", err)
+	}
+
+	sort.Slice(doc.Comments, func(i, j int) bool {
+		return bilibiliDanmakuAppearTime(doc.Comments[i].P) > bilibiliDanmakuAppearTime(doc.Comments[j].P)
+	})
+
+	limit := bilibiliDanmakuPreviewCount
+	if len(doc.Comments) < limit {
+		limit = len(doc.Comments)
+	}
+
+	preview := make([]string, 0, limit)
+	for i := 0; i < limit; i++ {
+		preview = append(preview, doc.Comments[i].Text)
+	}
+
+	return preview, nil
+}
+
+// bilibiliDanmakuAppearTime reads the appear-time (in seconds) out of a
+// danmaku's `p` attribute, whose fields are appear_time,mode,size,color,...
+func bilibiliDanmakuAppearTime(p string) float64 {
+	appearTime, _, _ := strings.Cut(p, ",")
+
+	t, _ := strconv.ParseFloat(appearTime, 64)
+
+	return t
+}
+
+func fetchBilibiliCid(bvid string) (int64, error) {
+	request, err := http.NewRequest("GET", fmt.Sprintf("https://api.bilibili.com/x/player/pagelist?bvid=%s", bvid), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("User-Agent", bilibiliUserAgent)
+	request.Header.Set("Referer", "https://www.bilibili.com/")
+
+	response, err := decodeJsonFromRequest[bilibiliPagelistResponseJson](defaultHTTPClient, request)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(response.Data) == 0 {
+		return 0, fmt.Errorf("no parts found for bvid %q", bvid)
+	}
+
+	return response.Data[0].Cid, nil
+}
@@ -0,0 +1,39 @@
+package glance
+
+import (
+	"fmt"
+	"time"
+)
+
+// bilibiliFormatRelativeTime renders a unix timestamp the same way
+// Bilibili's own UI does: a relative duration for anything recent, falling
+// back to an absolute date once it's more than a month old.
+func bilibiliFormatRelativeTime(unix int64) string {
+	if unix <= 0 {
+		return ""
+	}
+
+	since := time.Since(time.Unix(unix, 0))
+
+	switch {
+	case since < time.Minute:
+		return "刚刚"
+	case since < time.Hour:
+		return fmt.Sprintf("%d 分钟前", int(since.Minutes()))
+	case since < 24*time.Hour:
+		return fmt.Sprintf("%d 小时前", int(since.Hours()))
+	case since < 30*24*time.Hour:
+		return fmt.Sprintf("%d 天前", int(since.Hours()/24))
+	default:
+		return time.Unix(unix, 0).Format("2006-01-02")
+	}
+}
+
+// bilibiliFormatDuration renders a video length in seconds as mm:ss.
+func bilibiliFormatDuration(seconds int64) string {
+	if seconds <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
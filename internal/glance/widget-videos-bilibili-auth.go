@@ -0,0 +1,346 @@
+package glance
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// bilibiliUnauthorizedCode is the business error code Bilibili returns
+// (with an HTTP 200) when a cookie is missing, expired, or not logged in.
+const bilibiliUnauthorizedCode = -101
+
+type bilibiliDynamicFeedResponseJson struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Items []struct {
+			Modules struct {
+				ModuleAuthor struct {
+					Mid  int64  `json:"mid"`
+					Name string `json:"name"`
+				} `json:"module_author"`
+				ModuleDynamic struct {
+					Major struct {
+						Archive struct {
+							Bvid    string `json:"bvid"`
+							Title   string `json:"title"`
+							Cover   string `json:"cover"`
+							Pubdate int64  `json:"pubdate"`
+							Stat    struct {
+								Play    string `json:"play"`
+								Danmaku string `json:"danmaku"`
+							} `json:"stat"`
+						} `json:"archive"`
+					} `json:"major"`
+				} `json:"module_dynamic"`
+			} `json:"modules"`
+		} `json:"items"`
+	} `json:"data"`
+}
+
+type bilibiliHistoryResponseJson struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []struct {
+			Title      string `json:"title"`
+			Cover      string `json:"cover"`
+			AuthorName string `json:"author_name"`
+			AuthorMid  int64  `json:"author_mid"`
+			History    struct {
+				Bvid string `json:"bvid"`
+			} `json:"history"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+type bilibiliWatchLaterResponseJson struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []struct {
+			Pic   string `json:"pic"`
+			Title string `json:"title"`
+			Bvid  string `json:"bvid"`
+			Owner struct {
+				Mid  int64  `json:"mid"`
+				Name string `json:"name"`
+			} `json:"owner"`
+			Stat struct {
+				View    int64 `json:"view"`
+				Danmaku int64 `json:"danmaku"`
+				Like    int64 `json:"like"`
+			} `json:"stat"`
+			Pubdate int64 `json:"pubdate"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+type bilibiliFavFoldersResponseJson struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		List []struct {
+			Id    int64  `json:"id"`
+			Title string `json:"title"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+type bilibiliFavResourceResponseJson struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Medias []struct {
+			Title string `json:"title"`
+			Cover string `json:"cover"`
+			Bvid  string `json:"bvid"`
+			Upper struct {
+				Mid  int64  `json:"mid"`
+				Name string `json:"name"`
+			} `json:"upper"`
+			CntInfo struct {
+				Play    int64 `json:"play"`
+				Danmaku int64 `json:"danmaku"`
+			} `json:"cnt_info"`
+		} `json:"medias"`
+	} `json:"data"`
+}
+
+// bilibiliAuthenticatedRequest builds a WBI-signed, cookie-authenticated
+// GET request for one of the personal-feed endpoints.
+func bilibiliAuthenticatedRequest(rawUrl string, cookie string) (*http.Request, error) {
+	signedUrl, err := defaultBilibiliWBISigner.signURL(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest("GET", signedUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("User-Agent", bilibiliUserAgent)
+	request.Header.Set("Referer", "https://www.bilibili.com/")
+	request.Header.Set("Cookie", cookie)
+
+	return request, nil
+}
+
+// fetchBilibiliAuthenticatedFeed fetches one page of the signed-in user's
+// own feed for the given classify (`dynamic`/`following`, `history`,
+// `favorites`, `later`). It returns errNoContent if the cookie is missing,
+// expired, or rejected by Bilibili.
+func fetchBilibiliAuthenticatedFeed(classify string, cookie string, favoriteFolder string) (bilibiliVideoList, error) {
+	switch classify {
+	case "dynamic", "following":
+		return fetchBilibiliDynamicFeed(cookie)
+	case "history":
+		return fetchBilibiliWatchHistory(cookie)
+	case "favorites":
+		return fetchBilibiliFavorites(cookie, favoriteFolder)
+	case "later":
+		return fetchBilibiliWatchLater(cookie)
+	}
+
+	return nil, fmt.Errorf("%w: unknown authenticated classify %q", errNoContent, classify)
+}
+
+func fetchBilibiliDynamicFeed(cookie string) (bilibiliVideoList, error) {
+	request, err := bilibiliAuthenticatedRequest("https://api.bilibili.com/x/polymer/web-dynamic/v1/feed/all?type=video", cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := decodeJsonFromRequest[bilibiliDynamicFeedResponseJson](defaultHTTPClient, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code == bilibiliUnauthorizedCode {
+		return nil, fmt.Errorf("%w: bilibili cookie rejected: %s", errNoContent, response.Message)
+	}
+
+	videos := make(bilibiliVideoList, 0, len(response.Data.Items))
+
+	for i := range response.Data.Items {
+		archive := response.Data.Items[i].Modules.ModuleDynamic.Major.Archive
+		author := response.Data.Items[i].Modules.ModuleAuthor
+
+		if archive.Bvid == "" {
+			continue
+		}
+
+		play, _ := strconv.ParseInt(archive.Stat.Play, 10, 64)
+		danmaku, _ := strconv.ParseInt(archive.Stat.Danmaku, 10, 64)
+
+		videos = append(videos, videoBilibili{
+			ThumbnailUrl: archive.Cover,
+			Title:        archive.Title,
+			Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", archive.Bvid),
+			Bvid:         archive.Bvid,
+			Author:       author.Name,
+			AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", author.Mid),
+			Views:        play,
+			Danmaku:      danmaku,
+			PublishDate:  archive.Pubdate,
+			PublishedAgo: bilibiliFormatRelativeTime(archive.Pubdate),
+		})
+	}
+
+	if len(videos) == 0 {
+		return nil, errNoContent
+	}
+
+	return videos, nil
+}
+
+func fetchBilibiliWatchHistory(cookie string) (bilibiliVideoList, error) {
+	request, err := bilibiliAuthenticatedRequest("https://api.bilibili.com/x/web-interface/history/cursor?type=archive", cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := decodeJsonFromRequest[bilibiliHistoryResponseJson](defaultHTTPClient, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code == bilibiliUnauthorizedCode {
+		return nil, fmt.Errorf("%w: bilibili cookie rejected: %s", errNoContent, response.Message)
+	}
+
+	videos := make(bilibiliVideoList, 0, len(response.Data.List))
+
+	for i := range response.Data.List {
+		v := &response.Data.List[i]
+
+		if v.History.Bvid == "" {
+			continue
+		}
+
+		videos = append(videos, videoBilibili{
+			ThumbnailUrl: v.Cover,
+			Title:        v.Title,
+			Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", v.History.Bvid),
+			Bvid:         v.History.Bvid,
+			Author:       v.AuthorName,
+			AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", v.AuthorMid),
+		})
+	}
+
+	if len(videos) == 0 {
+		return nil, errNoContent
+	}
+
+	return videos, nil
+}
+
+func fetchBilibiliWatchLater(cookie string) (bilibiliVideoList, error) {
+	request, err := bilibiliAuthenticatedRequest("https://api.bilibili.com/x/v2/history/toview", cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := decodeJsonFromRequest[bilibiliWatchLaterResponseJson](defaultHTTPClient, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code == bilibiliUnauthorizedCode {
+		return nil, fmt.Errorf("%w: bilibili cookie rejected: %s", errNoContent, response.Message)
+	}
+
+	videos := make(bilibiliVideoList, 0, len(response.Data.List))
+
+	for i := range response.Data.List {
+		v := &response.Data.List[i]
+
+		videos = append(videos, videoBilibili{
+			ThumbnailUrl: v.Pic,
+			Title:        v.Title,
+			Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", v.Bvid),
+			Bvid:         v.Bvid,
+			Author:       v.Owner.Name,
+			AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", v.Owner.Mid),
+			Views:        v.Stat.View,
+			Danmaku:      v.Stat.Danmaku,
+			Likes:        v.Stat.Like,
+			PublishDate:  v.Pubdate,
+			PublishedAgo: bilibiliFormatRelativeTime(v.Pubdate),
+		})
+	}
+
+	if len(videos) == 0 {
+		return nil, errNoContent
+	}
+
+	return videos, nil
+}
+
+// fetchBilibiliFavorites lists the signed-in user's favorite folders and
+// merges the contents of favoriteFolder (or all folders, if unset).
+func fetchBilibiliFavorites(cookie string, favoriteFolder string) (bilibiliVideoList, error) {
+	auth, err := resolveBilibiliAuth(cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	foldersUrl := fmt.Sprintf("https://api.bilibili.com/x/v3/fav/folder/created/list-all?up_mid=%d", auth.Mid)
+	foldersRequest, err := bilibiliAuthenticatedRequest(foldersUrl, cookie)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := decodeJsonFromRequest[bilibiliFavFoldersResponseJson](defaultHTTPClient, foldersRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if folders.Code == bilibiliUnauthorizedCode {
+		return nil, fmt.Errorf("%w: bilibili cookie rejected: %s", errNoContent, folders.Message)
+	}
+
+	videos := make(bilibiliVideoList, 0, len(folders.Data.List)*20)
+
+	for i := range folders.Data.List {
+		folder := &folders.Data.List[i]
+
+		if favoriteFolder != "" && folder.Title != favoriteFolder {
+			continue
+		}
+
+		resourceUrl := fmt.Sprintf("https://api.bilibili.com/x/v3/fav/resource/list?media_id=%d&pn=1&ps=20&platform=web", folder.Id)
+		resourceRequest, err := bilibiliAuthenticatedRequest(resourceUrl, cookie)
+		if err != nil {
+			return nil, err
+		}
+
+		resources, err := decodeJsonFromRequest[bilibiliFavResourceResponseJson](defaultHTTPClient, resourceRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range resources.Data.Medias {
+			media := &resources.Data.Medias[j]
+
+			videos = append(videos, videoBilibili{
+				ThumbnailUrl: media.Cover,
+				Title:        media.Title,
+				Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", media.Bvid),
+				Bvid:         media.Bvid,
+				Author:       media.Upper.Name,
+				AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", media.Upper.Mid),
+				Views:        media.CntInfo.Play,
+				Danmaku:      media.CntInfo.Danmaku,
+			})
+		}
+	}
+
+	if len(videos) == 0 {
+		return nil, errNoContent
+	}
+
+	return videos, nil
+}
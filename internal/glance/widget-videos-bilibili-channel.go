@@ -0,0 +1,190 @@
+package glance
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bilibiliNoRedirectClient is used only to resolve b23.tv short links: we
+// want the redirect target from the Location header, not the page it points to.
+var bilibiliNoRedirectClient = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+type bilibiliArcSearchResponseJson struct {
+	Data struct {
+		List struct {
+			Vlist []struct {
+				Pic     string `json:"pic"`
+				Title   string `json:"title"`
+				Bvid    string `json:"bvid"`
+				Author  string `json:"author"`
+				Mid     int64  `json:"mid"`
+				Play    int64  `json:"play"`
+				Comment int64  `json:"comment"`
+				Danmaku int64  `json:"video_review"`
+				Created int64  `json:"created"`
+			} `json:"vlist"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+// resolveBilibiliShortLink follows a single b23.tv redirect and returns the
+// URL it points to, without following that URL itself.
+func resolveBilibiliShortLink(shortUrl string) (string, error) {
+	if !strings.HasPrefix(shortUrl, "http") {
+		shortUrl = "https://" + shortUrl
+	}
+
+	request, err := http.NewRequest("GET", shortUrl, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("User-Agent", bilibiliUserAgent)
+
+	response, err := bilibiliNoRedirectClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	location := response.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("b23.tv link %q did not redirect", shortUrl)
+	}
+
+	return location, nil
+}
+
+// resolveBilibiliChannelMid turns a UP identifier - a bare mid, a
+// space.bilibili.com/<mid> URL, or a b23.tv/<code> short link pointing at a
+// user space - into the numeric mid used by the space API.
+func resolveBilibiliChannelMid(identifier string) (int64, error) {
+	identifier = strings.TrimSpace(identifier)
+
+	if mid, err := strconv.ParseInt(identifier, 10, 64); err == nil {
+		return mid, nil
+	}
+
+	target := identifier
+
+	if strings.Contains(identifier, "b23.tv/") {
+		resolved, err := resolveBilibiliShortLink(identifier)
+		if err != nil {
+			return 0, err
+		}
+
+		target = resolved
+	}
+
+	const spaceMarker = "space.bilibili.com/"
+
+	idx := strings.Index(target, spaceMarker)
+	if idx == -1 {
+		return 0, fmt.Errorf("could not resolve a bilibili mid from %q", identifier)
+	}
+
+	rest := strings.TrimPrefix(target[idx+len(spaceMarker):], "/")
+	if end := strings.IndexAny(rest, "/?#"); end != -1 {
+		rest = rest[:end]
+	}
+
+	mid, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse bilibili mid out of %q: %w", target, err)
+	}
+
+	return mid, nil
+}
+
+func getBilibiliChannelUploadsURL(mid int64) string {
+	return fmt.Sprintf("https://api.bilibili.com/x/space/wbi/arc/search?mid=%d&ps=30&pn=1&order=pubdate", mid)
+}
+
+// fetchBilibiliChannelUploads resolves each UP identifier in channels to a
+// mid and merges their upload lists, turning the widget into a creator
+// subscription feed alongside the site-wide rankings in Classify.
+func fetchBilibiliChannelUploads(channels []string) (bilibiliVideoList, error) {
+	requests := make([]*http.Request, 0, len(channels))
+	var failed int
+
+	for i := range channels {
+		mid, err := resolveBilibiliChannelMid(channels[i])
+		if err != nil {
+			failed++
+			slog.Error("Failed to resolve bilibili channel", "channel", channels[i], "error", err)
+			continue
+		}
+
+		feedUrl := getBilibiliChannelUploadsURL(mid)
+
+		signedUrl, err := defaultBilibiliWBISigner.signURL(feedUrl)
+		if err != nil {
+			slog.Error("Failed to sign bilibili channel request, falling back to unsigned", "channel", channels[i], "error", err)
+			signedUrl = feedUrl
+		}
+
+		request, _ := http.NewRequest("GET", signedUrl, nil)
+		request.Header.Set("User-Agent", bilibiliUserAgent)
+		request.Header.Set("Referer", "https://www.bilibili.com/")
+		requests = append(requests, request)
+	}
+
+	if len(requests) == 0 {
+		return nil, errNoContent
+	}
+
+	job := newJob(decodeJsonFromRequestTask[bilibiliArcSearchResponseJson](defaultHTTPClient), requests).withWorkers(30)
+	responses, errs, err := workerPoolDo(job)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoContent, err)
+	}
+
+	videos := make(bilibiliVideoList, 0, len(requests)*30)
+
+	for i := range responses {
+		if errs[i] != nil {
+			failed++
+			slog.Error("Failed to fetch bilibili channel uploads", "error", errs[i])
+			continue
+		}
+
+		response := responses[i]
+
+		for j := range response.Data.List.Vlist {
+			v := &response.Data.List.Vlist[j]
+
+			videos = append(videos, videoBilibili{
+				ThumbnailUrl: v.Pic,
+				Title:        v.Title,
+				Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", v.Bvid),
+				Bvid:         v.Bvid,
+				Author:       v.Author,
+				AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", v.Mid),
+				Views:        v.Play,
+				Danmaku:      v.Danmaku,
+				Reply:        v.Comment,
+				PublishDate:  v.Created,
+				PublishedAgo: bilibiliFormatRelativeTime(v.Created),
+			})
+		}
+	}
+
+	if len(videos) == 0 {
+		return nil, errNoContent
+	}
+
+	if failed > 0 {
+		return videos, fmt.Errorf("%w: failed to resolve or fetch %d channels", errPartialContent, failed)
+	}
+
+	return videos, nil
+}
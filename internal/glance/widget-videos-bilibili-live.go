@@ -0,0 +1,220 @@
+package glance
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+var (
+	videosBilibiliLiveWidgetTemplate     = mustParseTemplate("bilibili-live.html", "widget-base.html", "bilibili-live-card-contents.html")
+	videosBilibiliLiveWidgetGridTemplate = mustParseTemplate("bilibili-live-grid.html", "widget-base.html", "bilibili-live-card-contents.html")
+)
+
+// bilibiliLiveParentAreas maps the friendly top-level live area names a
+// user can put in `parent-areas` to their numeric parent_area_id, mirroring
+// bilibiliPartitionRids for the video ranking widget.
+var bilibiliLiveParentAreas = map[string]int{
+	"网游": 2,
+	"手游": 3,
+	"单机": 6,
+	"娱乐": 1,
+	"生活": 10,
+	"知识": 11,
+	"赛事": 13,
+	"电台": 5,
+	"虚拟": 9,
+}
+
+type videosBilibiliLiveWidget struct {
+	widgetBase        `yaml:",inline"`
+	Streams           bilibiliLiveStreamList `yaml:"-"`
+	ParentAreas       []string               `yaml:"parent-areas"`
+	Style             string                 `yaml:"style"`
+	CollapseAfter     int                    `yaml:"collapse-after"`
+	CollapseAfterRows int                    `yaml:"collapse-after-rows"`
+	Limit             int                    `yaml:"limit"`
+	MinViewers        int64                  `yaml:"min-viewers"`
+	Sort              string                 `yaml:"sort"`
+}
+
+func (widget *videosBilibiliLiveWidget) initialize() error {
+	widget.withTitle("Bilibili Live").withCacheDuration(10 * time.Minute)
+
+	if widget.Limit <= 0 {
+		widget.Limit = 25
+	}
+
+	if widget.CollapseAfterRows == 0 || widget.CollapseAfterRows < -1 {
+		widget.CollapseAfterRows = 4
+	}
+
+	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
+		widget.CollapseAfter = 7
+	}
+
+	switch widget.Sort {
+	case "online", "live_time":
+	default:
+		widget.Sort = "online"
+	}
+
+	return nil
+}
+
+func (widget *videosBilibiliLiveWidget) update(ctx context.Context) {
+	streams, err := fetchBilibiliLiveStreams(widget.ParentAreas, widget.Sort, widget.MinViewers)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	if len(streams) > widget.Limit {
+		streams = streams[:widget.Limit]
+	}
+
+	widget.Streams = streams
+}
+
+func (widget *videosBilibiliLiveWidget) Render() template.HTML {
+	var template *template.Template
+
+	switch widget.Style {
+	case "grid-cards":
+		template = videosBilibiliLiveWidgetGridTemplate
+	default:
+		template = videosBilibiliLiveWidgetTemplate
+	}
+
+	return widget.renderTemplate(widget, template)
+}
+
+type bilibiliLiveListResponseJson struct {
+	Data struct {
+		List []struct {
+			RoomId     int64  `json:"roomid"`
+			Uname      string `json:"uname"`
+			Title      string `json:"title"`
+			Cover      string `json:"cover"`
+			Online     int64  `json:"online"`
+			AreaName   string `json:"area_name"`
+			ParentName string `json:"parent_name"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+// videoBilibiliLive has its own card partial (bilibili-live-card-contents.html)
+// rather than reusing videoBilibili's, since a live room has no bvid,
+// duration or upload-time fields - only what the live API exposes.
+type videoBilibiliLive struct {
+	ThumbnailUrl string
+	Title        string
+	Url          string
+	Author       string
+	AreaName     string
+	Viewers      int64
+}
+
+type bilibiliLiveStreamList []videoBilibiliLive
+
+func (v bilibiliLiveStreamList) sortByViewers() bilibiliLiveStreamList {
+	sort.Slice(v, func(i, j int) bool {
+		return v[i].Viewers > v[j].Viewers
+	})
+
+	return v
+}
+
+func getBilibiliLiveAreaURL(parentAreaId int, sortBy string) string {
+	return fmt.Sprintf(
+		"https://api.live.bilibili.com/xlive/web-interface/v1/second/getList?platform=web&parent_area_id=%d&area_id=0&sort_type=%s&page=1",
+		parentAreaId, sortBy,
+	)
+}
+
+// fetchBilibiliLiveStreams lists currently-live rooms across parentAreas
+// (falling back to "娱乐" when none are configured), filters out rooms
+// below minViewers and, for the "online" ordering, sorts the rest by current
+// viewer count; for "live_time" the API's own order is kept.
+func fetchBilibiliLiveStreams(parentAreas []string, sortBy string, minViewers int64) (bilibiliLiveStreamList, error) {
+	if len(parentAreas) == 0 {
+		parentAreas = []string{"娱乐"}
+	}
+
+	requests := make([]*http.Request, 0, len(parentAreas))
+
+	for i := range parentAreas {
+		areaId, ok := bilibiliLiveParentAreas[parentAreas[i]]
+		if !ok {
+			slog.Error("Unknown bilibili live parent area", "area", parentAreas[i])
+			continue
+		}
+
+		feedUrl := getBilibiliLiveAreaURL(areaId, sortBy)
+		request, _ := http.NewRequest("GET", feedUrl, nil)
+		request.Header.Set("User-Agent", bilibiliUserAgent)
+		request.Header.Set("Referer", "https://live.bilibili.com/")
+		requests = append(requests, request)
+	}
+
+	if len(requests) == 0 {
+		return nil, errNoContent
+	}
+
+	job := newJob(decodeJsonFromRequestTask[bilibiliLiveListResponseJson](defaultHTTPClient), requests).withWorkers(30)
+	responses, errs, err := workerPoolDo(job)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoContent, err)
+	}
+
+	streams := make(bilibiliLiveStreamList, 0, len(requests)*30)
+	var failed int
+
+	for i := range responses {
+		if errs[i] != nil {
+			failed++
+			slog.Error("Failed to fetch bilibili live area", "error", errs[i])
+			continue
+		}
+
+		response := responses[i]
+
+		for j := range response.Data.List {
+			room := &response.Data.List[j]
+
+			if room.Online < minViewers {
+				continue
+			}
+
+			streams = append(streams, videoBilibiliLive{
+				ThumbnailUrl: room.Cover,
+				Title:        room.Title,
+				Url:          fmt.Sprintf("https://live.bilibili.com/%d", room.RoomId),
+				Author:       room.Uname,
+				AreaName:     room.ParentName + " / " + room.AreaName,
+				Viewers:      room.Online,
+			})
+		}
+	}
+
+	if len(streams) == 0 {
+		return nil, errNoContent
+	}
+
+	// Only re-sort by viewer count for the "online" ordering - for
+	// "live_time" the per-area lists are already in the order the API
+	// returned them in, and re-sorting here would silently override that.
+	if sortBy == "online" {
+		streams.sortByViewers()
+	}
+
+	if failed > 0 {
+		return streams, fmt.Errorf("%w: missing streams from %d areas", errPartialContent, failed)
+	}
+
+	return streams, nil
+}
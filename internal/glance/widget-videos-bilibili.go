@@ -7,24 +7,45 @@ import (
 	"log/slog"
 	"net/http"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var (
-	videosBilibiliWidgetTemplate             = mustParseTemplate("bilibili.html", "widget-base.html", "bilibili-card-contents.html")
-	videosBilibiliWidgetGridTemplate         = mustParseTemplate("bilibili-grid.html", "widget-base.html", "bilibili-card-contents.html")
+	videosBilibiliWidgetTemplate     = mustParseTemplate("bilibili.html", "widget-base.html", "bilibili-card-contents.html")
+	videosBilibiliWidgetGridTemplate = mustParseTemplate("bilibili-grid.html", "widget-base.html", "bilibili-card-contents.html")
 )
 
 type videosBilibiliWidget struct {
-	widgetBase        `yaml:",inline"`
-	Videos            bilibiliVideoList `yaml:"-"`
-	VideoUrlTemplate  string    `yaml:"video-url-template"`
-	Style             string    `yaml:"style"`
-	CollapseAfter     int       `yaml:"collapse-after"`
-	CollapseAfterRows int       `yaml:"collapse-after-rows"`
-	Classify          []string  `yaml:"classify"`
-	Limit             int       `yaml:"limit"`
-	IncludeShorts     bool      `yaml:"include-shorts"`
+	widgetBase            `yaml:",inline"`
+	Videos                bilibiliVideoList `yaml:"-"`
+	VideoUrlTemplate      string            `yaml:"video-url-template"`
+	Style                 string            `yaml:"style"`
+	CollapseAfter         int               `yaml:"collapse-after"`
+	CollapseAfterRows     int               `yaml:"collapse-after-rows"`
+	Classify              []string          `yaml:"classify"`
+	Limit                 int               `yaml:"limit"`
+	IncludeShorts         bool              `yaml:"include-shorts"`
+	Sort                  string            `yaml:"sort"`
+	Cookie                string            `yaml:"cookie"`
+	SessData              string            `yaml:"sessdata"`
+	BiliJct               string            `yaml:"bili-jct"`
+	FavoriteFolder        string            `yaml:"favorite-folder"`
+	Channels              []string          `yaml:"channels"`
+	IncludeDanmakuPreview bool              `yaml:"danmaku-preview"`
+}
+
+// bilibiliAuthenticatedClassifies are the classify values that read from the
+// signed-in user's own feeds rather than a public ranking, and therefore
+// require widget.Cookie/SessData to be configured. The one exception is
+// "history", which without credentials reads the public all-time ranking.
+var bilibiliAuthenticatedClassifies = map[string]bool{
+	"dynamic":   true,
+	"following": true,
+	"history":   true,
+	"favorites": true,
+	"later":     true,
 }
 
 func (widget *videosBilibiliWidget) initialize() error {
@@ -42,20 +63,54 @@ func (widget *videosBilibiliWidget) initialize() error {
 		widget.CollapseAfter = 7
 	}
 
+	switch widget.Sort {
+	case "view", "like", "pubdate", "danmaku":
+	default:
+		widget.Sort = "view"
+	}
+
 	return nil
 }
 
+// cookieHeader returns the Cookie header value to authenticate with, or an
+// empty string if the widget has no credentials configured.
+func (widget *videosBilibiliWidget) cookieHeader() string {
+	return bilibiliCookieHeader(widget.Cookie, widget.SessData, widget.BiliJct)
+}
+
 func (widget *videosBilibiliWidget) update(ctx context.Context) {
-	videos, err := fetchBilibiliClassifyUploads(widget.Classify, widget.VideoUrlTemplate, widget.IncludeShorts)
+	var videos bilibiliVideoList
+	var err error
+
+	if len(widget.Classify) > 0 {
+		videos, err = fetchBilibiliClassifyUploads(widget.Classify, widget.VideoUrlTemplate, widget.IncludeShorts, widget.Sort, widget.cookieHeader(), widget.FavoriteFolder)
+	}
+
+	if len(widget.Channels) > 0 {
+		channelVideos, channelErr := fetchBilibiliChannelUploads(widget.Channels)
+
+		switch {
+		case channelErr == nil:
+			videos = append(videos, channelVideos...)
+		case err == nil:
+			err = channelErr
+		}
+	}
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
 	}
 
+	videos.sortBy(widget.Sort)
+
 	if len(videos) > widget.Limit {
 		videos = videos[:widget.Limit]
 	}
 
+	if widget.IncludeDanmakuPreview {
+		attachBilibiliDanmakuPreviews(videos)
+	}
+
 	widget.Videos = videos
 }
 
@@ -72,109 +127,326 @@ func (widget *videosBilibiliWidget) Render() template.HTML {
 	return widget.renderTemplate(widget, template)
 }
 
+// bilibiliRegionArchivesResponseJson matches the response shape of
+// x/web-interface/dynamic/region (used for the ":latest" partition suffix),
+// which nests its video list under "archives" rather than "list".
+type bilibiliRegionArchivesResponseJson struct {
+	Data struct {
+		Archives []struct {
+			Pic   string `json:"pic"`
+			Title string `json:"title"`
+			Bvid  string `json:"bvid"`
+			Owner struct {
+				Mid  int64  `json:"mid"`
+				Name string `json:"name"`
+			} `json:"owner"`
+			Stat struct {
+				View     int64 `json:"view"`
+				Danmaku  int64 `json:"danmaku"`
+				Like     int64 `json:"like"`
+				Coin     int64 `json:"coin"`
+				Favorite int64 `json:"favorite"`
+				Reply    int64 `json:"reply"`
+			} `json:"stat"`
+			Pubdate  int64 `json:"pubdate"`
+			Duration int64 `json:"duration"`
+		} `json:"archives"`
+	} `json:"data"`
+}
+
 type bilibiliFeedResponseJson struct {
 	Data struct {
 		List []struct {
-			Pic   string `json:"pic"`    // 封面图URL
-			Title string `json:"title"`  // 视频标题
-			Bvid  string `json:"bvid"`   // 视频唯一标识
+			Pic   string `json:"pic"`   // 封面图URL
+			Title string `json:"title"` // 视频标题
+			Bvid  string `json:"bvid"`  // 视频唯一标识
 			Owner struct {
 				Mid  int64  `json:"mid"`  // UP主ID
 				Name string `json:"name"` // UP主名称
 			} `json:"owner"`
 			Stats struct {
-				View int64 `json:"view"` // 播放量
-				Danmaku int64 `json:"danmaku"` // 弹幕数
+				View     int64 `json:"view"`     // 播放量
+				Danmaku  int64 `json:"danmaku"`  // 弹幕数
+				Like     int64 `json:"like"`     // 点赞数
+				Coin     int64 `json:"coin"`     // 投币数
+				Favorite int64 `json:"favorite"` // 收藏数
+				Reply    int64 `json:"reply"`    // 评论数
 			} `json:"stat"`
+			Pubdate  int64 `json:"pubdate"`  // 发布时间
+			Duration int64 `json:"duration"` // 视频时长（秒）
 		} `json:"list"`
 	} `json:"data"`
 }
 
+// bilibiliPartitionRids maps the friendly 分区 (partition) names a user can
+// put in `classify` to their numeric rid, so config doesn't require knowing
+// Bilibili's internal ids. A numeric string in `classify` is used as a rid
+// directly, bypassing this table entirely.
+var bilibiliPartitionRids = map[string]int{
+	"动画": 1,
+	"音乐": 3,
+	"舞蹈": 129,
+	"游戏": 4,
+	"鬼畜": 119,
+	"知识": 36,
+	"科技": 188,
+	"运动": 234,
+	"生活": 160,
+	"美食": 211,
+	"动物": 217,
+	"汽车": 223,
+	"时尚": 155,
+	"娱乐": 5,
+	"影视": 181,
+}
+
+// bilibiliPartitionRid resolves classify to a partition rid, either by
+// looking up a friendly name in bilibiliPartitionRids or by parsing it as a
+// numeric rid directly. A ":latest" suffix (e.g. "动画:latest") asks for the
+// partition's newest uploads instead of its ranking.
+func bilibiliPartitionRid(classify string) (rid int, latest bool, ok bool) {
+	name, latest := strings.CutSuffix(classify, ":latest")
+
+	if rid, err := strconv.Atoi(name); err == nil {
+		return rid, latest, true
+	}
+
+	if rid, found := bilibiliPartitionRids[name]; found {
+		return rid, latest, true
+	}
+
+	return 0, false, false
+}
+
+// bilibiliFeedIsRegionArchives reports whether classify resolves to the
+// ":latest" region feed, whose response is shaped differently
+// (bilibiliRegionArchivesResponseJson) than the ranking/popular endpoints.
+func bilibiliFeedIsRegionArchives(classify string) bool {
+	_, latest, ok := bilibiliPartitionRid(classify)
+	return ok && latest
+}
+
 func getBilibiliFeedURL(classify string) string {
-	wts := time.Now().Unix()
 	switch classify {
 	case "all":
-		return fmt.Sprintf("https://api.bilibili.com/x/web-interface/popular?ps=20&pn=1&wts=%d", wts)
+		return "https://api.bilibili.com/x/web-interface/popular?ps=20&pn=1"
 	case "weekly":
 		start := time.Date(2019, time.March, 22, 0, 0, 0, 0, time.UTC)
 		now := time.Now().UTC()
 		duration := now.Sub(start)
 		days := int(duration.Hours() / 24)
-		period := days/7
-		return fmt.Sprintf("https://api.bilibili.com/x/web-interface/popular/series/one?number=%d&wts=%d", period, wts)
+		period := days / 7
+		return fmt.Sprintf("https://api.bilibili.com/x/web-interface/popular/series/one?number=%d", period)
 	case "history":
-		return fmt.Sprintf("https://api.bilibili.com/x/web-interface/popular/precious?page_size=100&page=1&wts=%d", wts)
-	default:
-		return fmt.Sprintf("https://api.bilibili.com/x/web-interface/ranking/v2?rid=0&type=all&wts=%d", wts)
+		return "https://api.bilibili.com/x/web-interface/popular/precious?page_size=100&page=1"
+	}
+
+	if rid, latest, ok := bilibiliPartitionRid(classify); ok {
+		if latest {
+			return fmt.Sprintf("https://api.bilibili.com/x/web-interface/dynamic/region?rid=%d", rid)
+		}
+
+		return fmt.Sprintf("https://api.bilibili.com/x/web-interface/ranking/v2?rid=%d&type=all", rid)
 	}
+
+	return "https://api.bilibili.com/x/web-interface/ranking/v2?rid=0&type=all"
 }
 
 type videoBilibili struct {
-	ThumbnailUrl string
-	Title        string
-	Url          string
-	Author       string
-	AuthorUrl    string
-	Views        int64
-	Danmaku		 int64
-	Desc		 string
+	ThumbnailUrl   string
+	Title          string
+	Url            string
+	Bvid           string
+	Author         string
+	AuthorUrl      string
+	Views          int64
+	Danmaku        int64
+	Likes          int64
+	Coin           int64
+	Favorite       int64
+	Reply          int64
+	PublishDate    int64
+	PublishedAgo   string
+	Duration       string
+	DanmakuPreview []string
+	Desc           string
 }
 
 type bilibiliVideoList []videoBilibili
 
-func (v bilibiliVideoList) sortByView() bilibiliVideoList {
-	sort.Slice(v, func(i, j int) bool {
-		return v[i].Views > v[j].Views
-	})
+// sortBy orders the list by the given key (`view`, `like`, `pubdate`,
+// `danmaku`), falling back to view count for anything else.
+func (v bilibiliVideoList) sortBy(key string) bilibiliVideoList {
+	var less func(i, j int) bool
+
+	switch key {
+	case "like":
+		less = func(i, j int) bool { return v[i].Likes > v[j].Likes }
+	case "pubdate":
+		less = func(i, j int) bool { return v[i].PublishDate > v[j].PublishDate }
+	case "danmaku":
+		less = func(i, j int) bool { return v[i].Danmaku > v[j].Danmaku }
+	default:
+		less = func(i, j int) bool { return v[i].Views > v[j].Views }
+	}
+
+	sort.Slice(v, less)
 
 	return v
 }
 
+// history: https://api.bilibili.com/x/web-interface/popular/precious?page_size=100&page=1&wts=1742301563
+// rank/all: https://api.bilibili.com/x/web-interface/ranking/v2?rid=0&type=all&wts=1742301628
+//
 //all:https://api.bilibili.com/x/web-interface/popular?ps=20&pn=1&wts=1742301430
 //weekly:https://api.bilibili.com/x/web-interface/popular/series/one?number=312&wts=1742227080
-//history: https://api.bilibili.com/x/web-interface/popular/precious?page_size=100&page=1&wts=1742301563
-//rank/all: https://api.bilibili.com/x/web-interface/ranking/v2?rid=0&type=all&wts=1742301628
-func fetchBilibiliClassifyUploads(classify []string, videoUrlTemplate string, includeShorts bool) (bilibiliVideoList, error) {
-	requests := make([]*http.Request, 0, len(classify))
+func fetchBilibiliClassifyUploads(classify []string, videoUrlTemplate string, includeShorts bool, sortBy string, cookie string, favoriteFolder string) (bilibiliVideoList, error) {
+	rankingClassify := make([]string, 0, len(classify))
+	archivesClassify := make([]string, 0, len(classify))
+	videos := make(bilibiliVideoList, 0, len(classify)*15)
+	var failed int
 
+	// "history" is ambiguous: with no cookie configured it keeps its
+	// long-standing meaning of the site-wide "all-time favorites" ranking,
+	// but once credentials are present it switches to the user's own watch
+	// history, matching the other authenticated classify values.
 	for i := range classify {
-		feedUrl := getBilibiliFeedURL(classify[i])
-		request, _ := http.NewRequest("GET", feedUrl, nil)
-		request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-		request.Header.Set("Referer", "https://www.bilibili.com/")
-		requests = append(requests, request)
-	}
+		if bilibiliAuthenticatedClassifies[classify[i]] && (cookie != "" || classify[i] != "history") {
+			if cookie == "" {
+				failed++
+				slog.Error("Bilibili classify requires credentials", "classify", classify[i])
+				continue
+			}
+
+			authVideos, err := fetchBilibiliAuthenticatedFeed(classify[i], cookie, favoriteFolder)
+			if err != nil {
+				failed++
+				slog.Error("Failed to fetch bilibili authenticated feed", "classify", classify[i], "error", err)
+				continue
+			}
+
+			videos = append(videos, authVideos...)
+			continue
+		}
 
-	job := newJob(decodeJsonFromRequestTask[bilibiliFeedResponseJson](defaultHTTPClient), requests).withWorkers(30)
-	responses, errs, err := workerPoolDo(job)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", errNoContent, err)
+		if bilibiliFeedIsRegionArchives(classify[i]) {
+			archivesClassify = append(archivesClassify, classify[i])
+		} else {
+			rankingClassify = append(rankingClassify, classify[i])
+		}
 	}
 
-	videos := make(bilibiliVideoList, 0, len(classify)*15)
-	var failed int
+	if len(rankingClassify) > 0 {
+		requests := make([]*http.Request, 0, len(rankingClassify))
 
-	for i := range responses {
-		if errs[i] != nil {
-			failed++
-			slog.Error("Failed to fetch bilibi", "classify", classify[i], "error", errs[i])
-			continue
+		for i := range rankingClassify {
+			feedUrl := getBilibiliFeedURL(rankingClassify[i])
+
+			signedUrl, err := defaultBilibiliWBISigner.signURL(feedUrl)
+			if err != nil {
+				slog.Error("Failed to sign bilibili request, falling back to unsigned", "classify", rankingClassify[i], "error", err)
+				signedUrl = feedUrl
+			}
+
+			request, _ := http.NewRequest("GET", signedUrl, nil)
+			request.Header.Set("User-Agent", bilibiliUserAgent)
+			request.Header.Set("Referer", "https://www.bilibili.com/")
+			requests = append(requests, request)
+		}
+
+		job := newJob(decodeJsonFromRequestTask[bilibiliFeedResponseJson](defaultHTTPClient), requests).withWorkers(30)
+		responses, errs, err := workerPoolDo(job)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errNoContent, err)
 		}
 
-		response := responses[i]
+		for i := range responses {
+			if errs[i] != nil {
+				failed++
+				slog.Error("Failed to fetch bilibi", "classify", rankingClassify[i], "error", errs[i])
+				continue
+			}
+
+			response := responses[i]
+
+			for j := range response.Data.List {
+				v := &response.Data.List[j]
+
+				videos = append(videos, videoBilibili{
+					ThumbnailUrl: v.Pic,
+					Title:        v.Title,
+					Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", v.Bvid),
+					Bvid:         v.Bvid,
+					Author:       v.Owner.Name,
+					AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", v.Owner.Mid),
+					Views:        v.Stats.View,
+					Danmaku:      v.Stats.Danmaku,
+					Likes:        v.Stats.Like,
+					Coin:         v.Stats.Coin,
+					Favorite:     v.Stats.Favorite,
+					Reply:        v.Stats.Reply,
+					PublishDate:  v.Pubdate,
+					PublishedAgo: bilibiliFormatRelativeTime(v.Pubdate),
+					Duration:     bilibiliFormatDuration(v.Duration),
+				})
+			}
+		}
+	}
+
+	if len(archivesClassify) > 0 {
+		requests := make([]*http.Request, 0, len(archivesClassify))
+
+		for i := range archivesClassify {
+			feedUrl := getBilibiliFeedURL(archivesClassify[i])
 
-		for j := range response.Data.List {
-			v := &response.Data.List[j]
+			signedUrl, err := defaultBilibiliWBISigner.signURL(feedUrl)
+			if err != nil {
+				slog.Error("Failed to sign bilibili request, falling back to unsigned", "classify", archivesClassify[i], "error", err)
+				signedUrl = feedUrl
+			}
+
+			request, _ := http.NewRequest("GET", signedUrl, nil)
+			request.Header.Set("User-Agent", bilibiliUserAgent)
+			request.Header.Set("Referer", "https://www.bilibili.com/")
+			requests = append(requests, request)
+		}
+
+		job := newJob(decodeJsonFromRequestTask[bilibiliRegionArchivesResponseJson](defaultHTTPClient), requests).withWorkers(30)
+		responses, errs, err := workerPoolDo(job)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errNoContent, err)
+		}
 
-			videos = append(videos, videoBilibili{
-				ThumbnailUrl: v.Pic,
-				Title:        v.Title,
-				Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", v.Bvid),
-				Author:       v.Owner.Name,
-				AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", v.Owner.Mid),
-				Views:        v.Stats.View,
-				Danmaku:        v.Stats.Danmaku,
-			})
+		for i := range responses {
+			if errs[i] != nil {
+				failed++
+				slog.Error("Failed to fetch bilibi", "classify", archivesClassify[i], "error", errs[i])
+				continue
+			}
+
+			response := responses[i]
+
+			for j := range response.Data.Archives {
+				v := &response.Data.Archives[j]
+
+				videos = append(videos, videoBilibili{
+					ThumbnailUrl: v.Pic,
+					Title:        v.Title,
+					Url:          fmt.Sprintf("https://www.bilibili.com/video/%s", v.Bvid),
+					Bvid:         v.Bvid,
+					Author:       v.Owner.Name,
+					AuthorUrl:    fmt.Sprintf("https://space.bilibili.com/%d", v.Owner.Mid),
+					Views:        v.Stat.View,
+					Danmaku:      v.Stat.Danmaku,
+					Likes:        v.Stat.Like,
+					Coin:         v.Stat.Coin,
+					Favorite:     v.Stat.Favorite,
+					Reply:        v.Stat.Reply,
+					PublishDate:  v.Pubdate,
+					PublishedAgo: bilibiliFormatRelativeTime(v.Pubdate),
+					Duration:     bilibiliFormatDuration(v.Duration),
+				})
+			}
 		}
 	}
 
@@ -182,7 +454,7 @@ func fetchBilibiliClassifyUploads(classify []string, videoUrlTemplate string, in
 		return nil, errNoContent
 	}
 
-	videos.sortByView()
+	videos.sortBy(sortBy)
 
 	if failed > 0 {
 		return videos, fmt.Errorf("%w: missing videos from %d channels", errPartialContent, failed)